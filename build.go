@@ -0,0 +1,120 @@
+package testcontainer
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/api/types"
+)
+
+// FromDockerfile builds an image from a local build context instead of
+// pulling one from a registry. Set it on RequestContainer to use a
+// project-local image that isn't published anywhere.
+type FromDockerfile struct {
+	// Context is the directory tar'd up and sent to the daemon as the
+	// build context.
+	Context string
+	// Dockerfile is relative to Context. Defaults to "Dockerfile".
+	Dockerfile string
+	// BuildArgs are passed through to `docker build --build-arg`.
+	BuildArgs map[string]*string
+	// PrintBuildLog streams the build output to stdout as it happens.
+	PrintBuildLog bool
+	// Repo and Tag name the resulting image. Tag defaults to this
+	// session's UUID, so builds from different test processes don't
+	// collide; builds within one process share that default tag.
+	Repo string
+	Tag  string
+}
+
+// buildImage tars cfg.Context and builds it with the daemon, returning
+// the resulting image tag.
+func (p *DockerProvider) buildImage(ctx context.Context, cfg FromDockerfile) (string, error) {
+	dockerfile := cfg.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+
+	buildContext, err := tarBuildContext(cfg.Context)
+	if err != nil {
+		return "", fmt.Errorf("tar build context: %w", err)
+	}
+
+	repo := cfg.Repo
+	if repo == "" {
+		repo = "testcontainer-go"
+	}
+	tag := cfg.Tag
+	if tag == "" {
+		tag = sessionUUID()
+	}
+	imageTag := fmt.Sprintf("%s:%s", repo, tag)
+
+	resp, err := p.client.ImageBuild(ctx, buildContext, types.ImageBuildOptions{
+		Dockerfile: dockerfile,
+		BuildArgs:  cfg.BuildArgs,
+		Tags:       []string{imageTag},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	out := ioutil.Discard
+	if cfg.PrintBuildLog {
+		out = os.Stdout
+	}
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		return "", fmt.Errorf("read build log: %w", err)
+	}
+
+	return imageTag, nil
+}
+
+// tarBuildContext walks root and tars its contents for ImageBuild.
+func tarBuildContext(root string) (io.Reader, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = rel
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
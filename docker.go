@@ -1,77 +1,312 @@
 package testcontainer
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
+	"net/url"
 	"strconv"
-	"strings"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
+	dockererrdefs "github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainer-go/errdefs"
 	"github.com/testcontainers/testcontainer-go/wait"
 )
 
+// ContainerResources caps the resources a container may use.
+type ContainerResources struct {
+	// Memory is the memory limit in bytes.
+	Memory int64
+	// NanoCPUs is the CPU quota in units of 1e-9 CPUs.
+	NanoCPUs int64
+}
+
 // RequestContainer is the input object used to get a running container.
 type RequestContainer struct {
 	Env          map[string]string
 	ExportedPort []string
-	Cmd          string
+	// Cmd is passed to the container as-is, one argument per element. Use
+	// this instead of a single shell string so arguments containing
+	// spaces or quotes survive unmangled.
+	Cmd          []string
+	Entrypoint   []string
 	RegistryCred string
 	WaitingFor   wait.WaitStrategy
+
+	// FromDockerfile builds the container's image locally instead of
+	// pulling the image passed to RunContainer/CreateContainer.
+	FromDockerfile FromDockerfile
+
+	Mounts         []ContainerMount
+	Networks       []string
+	NetworkAliases map[string][]string
+	Files          []ContainerFile
+
+	User       string
+	Privileged bool
+	CapAdd     []string
+	CapDrop    []string
+	Labels     map[string]string
+	AutoRemove bool
+	WorkingDir string
+	Resources  ContainerResources
+
+	// SkipReaper opts this container out of reaper cleanup, e.g. for
+	// containers the caller intends to outlive the test process.
+	SkipReaper bool
+}
+
+// DockerProvider implements ContainerProvider against a local or
+// DOCKER_HOST-configured Docker daemon.
+type DockerProvider struct {
+	client *client.Client
+}
+
+// NewDockerProvider builds a DockerProvider from the environment, the same
+// way the docker CLI itself does (DOCKER_HOST, DOCKER_API_VERSION, ...).
+func NewDockerProvider() (*DockerProvider, error) {
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		return nil, err
+	}
+	return &DockerProvider{client: cli}, nil
 }
 
-// Container is the struct used to represent a single container.
-type Container struct {
-	// Container ID from Docker
-	ID string
-	// Cache to retrieve container infromation without re-fetching them from dockerd
-	raw *types.ContainerJSON
+// DockerContainer is the Container implementation backed by a real Docker
+// container.
+type DockerContainer struct {
+	// ID is the container ID from Docker
+	ID       string
+	provider *DockerProvider
 }
 
-func (c *Container) LivenessCheckPorts(ctx context.Context) (nat.PortSet, error) {
-	inspect, err := inspectContainer(ctx, c)
+// GetContainerID returns the Docker container ID.
+func (c *DockerContainer) GetContainerID() string {
+	return c.ID
+}
+
+// LivenessCheckPorts returns the container's exposed ports.
+//
+// Deprecated: call Inspect and read Config.ExposedPorts directly.
+func (c *DockerContainer) LivenessCheckPorts(ctx context.Context) (nat.PortSet, error) {
+	inspect, err := c.Inspect(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return inspect.Config.ExposedPorts, nil
 }
 
-// Terminate is used to kill the container. It is usally triggered by as defer function.
-func (c *Container) Terminate(ctx context.Context, t *testing.T) error {
-	cli, err := client.NewEnvClient()
+// Host returns the address the daemon's published ports are reachable
+// at. Published ports are bound on the daemon host, not the container's
+// internal bridge IP, so this is "localhost" for a local/unix-socket
+// daemon or the hostname from DOCKER_HOST for a remote one.
+func (c *DockerContainer) Host(ctx context.Context) (string, error) {
+	return daemonHost(c.provider.client), nil
+}
+
+func daemonHost(cli *client.Client) string {
+	u, err := url.Parse(cli.DaemonHost())
+	if err != nil || u.Hostname() == "" {
+		return "localhost"
+	}
+	switch u.Scheme {
+	case "tcp", "http", "https":
+		return u.Hostname()
+	default:
+		// unix:// and npipe:// daemons run on the local machine.
+		return "localhost"
+	}
+}
+
+// MappedPort returns the host port bound to the given container port.
+func (c *DockerContainer) MappedPort(ctx context.Context, port int) (int, error) {
+	return c.GetMappedPort(ctx, port)
+}
+
+// PortEndpoint builds a "<proto>://<host>:<port>" endpoint for the given
+// container port, or "<host>:<port>" if proto is empty.
+func (c *DockerContainer) PortEndpoint(ctx context.Context, port int, proto string) (string, error) {
+	host, err := c.Host(ctx)
 	if err != nil {
-		t.Error(err)
-		return err
+		return "", err
 	}
-	return cli.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{
-		Force: true,
-	})
+	mapped, err := c.MappedPort(ctx, port)
+	if err != nil {
+		return "", err
+	}
+	endpoint := fmt.Sprintf("%s:%d", host, mapped)
+	if proto != "" {
+		endpoint = fmt.Sprintf("%s://%s", proto, endpoint)
+	}
+	return endpoint, nil
 }
 
-func inspectContainer(ctx context.Context, c *Container) (*types.ContainerJSON, error) {
-	if c.raw != nil {
-		return c.raw, nil
+// Endpoint returns the PortEndpoint for the lowest-numbered exposed port.
+func (c *DockerContainer) Endpoint(ctx context.Context, proto string) (string, error) {
+	ports, err := c.LivenessCheckPorts(ctx)
+	if err != nil {
+		return "", err
 	}
-	cli, err := client.NewEnvClient()
+	if len(ports) == 0 {
+		return "", fmt.Errorf("container %s exposes no ports", c.ID)
+	}
+
+	lowest := -1
+	for port := range ports {
+		if lowest == -1 || port.Int() < lowest {
+			lowest = port.Int()
+		}
+	}
+	return c.PortEndpoint(ctx, lowest, proto)
+}
+
+// Start starts a previously created container.
+func (c *DockerContainer) Start(ctx context.Context) error {
+	return c.provider.client.ContainerStart(ctx, c.ID, types.ContainerStartOptions{})
+}
+
+// Stop stops a running container.
+func (c *DockerContainer) Stop(ctx context.Context) error {
+	return c.provider.client.ContainerStop(ctx, c.ID, nil)
+}
+
+// Inspect returns the full container state from dockerd. It never
+// caches, so callers always see the current port/IP/state after a
+// restart or network reattach.
+func (c *DockerContainer) Inspect(ctx context.Context) (*types.ContainerJSON, error) {
+	inspect, err := c.provider.client.ContainerInspect(ctx, c.ID)
 	if err != nil {
+		if dockererrdefs.IsNotFound(err) {
+			return nil, errdefs.WrapNotFound(err)
+		}
 		return nil, err
 	}
-	inspect, err := cli.ContainerInspect(ctx, c.ID)
+	return &inspect, nil
+}
+
+// Name returns the container's name, as assigned by dockerd.
+func (c *DockerContainer) Name(ctx context.Context) (string, error) {
+	inspect, err := c.Inspect(ctx)
+	if err != nil {
+		return "", err
+	}
+	return inspect.Name, nil
+}
+
+// Ports returns the container's current port bindings.
+func (c *DockerContainer) Ports(ctx context.Context) (nat.PortMap, error) {
+	inspect, err := c.Inspect(ctx)
 	if err != nil {
 		return nil, err
 	}
-	c.raw = &inspect
-	return c.raw, nil
+	return inspect.NetworkSettings.Ports, nil
+}
+
+// State returns the container's current state (running, exited, health, ...).
+func (c *DockerContainer) State(ctx context.Context) (*types.ContainerState, error) {
+	inspect, err := c.Inspect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return inspect.State, nil
+}
+
+// Logs returns the container's combined stdout/stderr, demultiplexed
+// from dockerd's stdcopy-framed stream so callers see clean text instead
+// of raw frame headers.
+func (c *DockerContainer) Logs(ctx context.Context) (io.ReadCloser, error) {
+	raw, err := c.provider.client.ContainerLogs(ctx, c.ID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(pw, pw, raw)
+		pw.CloseWithError(err)
+		raw.Close()
+	}()
+	return pr, nil
+}
+
+// Exec runs cmd inside the container and returns its exit code and
+// output. The output is fully drained before the exit code is read back,
+// since dockerd doesn't finalize it until the attached stream is
+// consumed.
+func (c *DockerContainer) Exec(ctx context.Context, cmd []string) (int, io.Reader, error) {
+	execResp, err := c.provider.client.ContainerExecCreate(ctx, c.ID, types.ExecConfig{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	attachResp, err := c.provider.client.ContainerExecAttach(ctx, execResp.ID, types.ExecStartCheck{})
+	if err != nil {
+		return 0, nil, err
+	}
+	defer attachResp.Close()
+
+	var output bytes.Buffer
+	if _, err := io.Copy(&output, attachResp.Reader); err != nil {
+		return 0, nil, err
+	}
+
+	for {
+		inspect, err := c.provider.client.ContainerExecInspect(ctx, execResp.ID)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !inspect.Running {
+			return inspect.ExitCode, &output, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// Terminate is used to kill the container. It is usally triggered by as defer function.
+func (c *DockerContainer) Terminate(ctx context.Context, t *testing.T) error {
+	err := c.provider.client.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{
+		Force: true,
+	})
+	if err != nil {
+		if dockererrdefs.IsNotFound(err) {
+			err = errdefs.WrapNotFound(err)
+		}
+		t.Error(err)
+		return err
+	}
+	return nil
 }
 
 // GetIPAddress returns the ip address for the running container.
-func (c *Container) GetIPAddress(ctx context.Context) (string, error) {
-	inspect, err := inspectContainer(ctx, c)
+//
+// Deprecated: call Inspect and read NetworkSettings.IPAddress directly,
+// or Host for the address published ports are reachable at.
+func (c *DockerContainer) GetIPAddress(ctx context.Context) (string, error) {
+	inspect, err := c.Inspect(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -79,9 +314,11 @@ func (c *Container) GetIPAddress(ctx context.Context) (string, error) {
 }
 
 // GetMappedPort returns PortBindings for the running Container.
-func (c *Container) GetMappedPort(ctx context.Context, port int) (int, error) {
+//
+// Deprecated: call MappedPort, which calls Inspect under the hood.
+func (c *DockerContainer) GetMappedPort(ctx context.Context, port int) (int, error) {
 	var binding int
-	inspect, err := inspectContainer(ctx, c)
+	inspect, err := c.Inspect(ctx)
 	if err != nil {
 		return binding, err
 	}
@@ -91,14 +328,15 @@ func (c *Container) GetMappedPort(ctx context.Context, port int) (int, error) {
 			return strconv.Atoi(val[0].HostPort)
 		}
 	}
-	return binding, fmt.Errorf("Unable to find mapped port: %d", port)
+	return binding, fmt.Errorf("%w: %d", errdefs.ErrPortNotMapped, port)
 }
 
-// RunContainer takes a RequestContainer as input and it runs a container via the docker sdk
-func RunContainer(ctx context.Context, containerImage string, input RequestContainer) (*Container, error) {
-	cli, err := client.NewEnvClient()
-	if err != nil {
-		return nil, err
+// CreateContainer creates a container from input without starting it.
+func (p *DockerProvider) CreateContainer(ctx context.Context, containerImage string, input RequestContainer) (Container, error) {
+	if !input.SkipReaper {
+		if _, err := getReaper(ctx, p.client); err != nil {
+			return nil, err
+		}
 	}
 
 	exposedPorts, portBindings, err := nat.ParsePortSpecs(input.ExportedPort)
@@ -112,53 +350,168 @@ func RunContainer(ctx context.Context, containerImage string, input RequestConta
 		env = append(env, envKey+"="+envVar)
 	}
 
+	labels := map[string]string{}
+	if !input.SkipReaper {
+		labels = sessionLabels()
+	}
+	labels[reuseHashLabel] = reuseHash(containerImage, input)
+	for k, v := range input.Labels {
+		labels[k] = v
+	}
+
 	dockerInput := &container.Config{
 		Image:        containerImage,
 		Env:          env,
 		ExposedPorts: exposedPorts,
+		Labels:       labels,
+		Cmd:          input.Cmd,
+		Entrypoint:   input.Entrypoint,
+		User:         input.User,
+		WorkingDir:   input.WorkingDir,
 	}
 
-	if input.Cmd != "" {
-		dockerInput.Cmd = strings.Split(input.Cmd, " ")
+	if input.FromDockerfile.Context != "" {
+		builtTag, err := p.buildImage(ctx, input.FromDockerfile)
+		if err != nil {
+			return nil, fmt.Errorf("build image: %w", err)
+		}
+		dockerInput.Image = builtTag
+	} else {
+		pullOpt := types.ImagePullOptions{}
+		if input.RegistryCred != "" {
+			pullOpt.RegistryAuth = input.RegistryCred
+		}
+		pull, err := p.client.ImagePull(ctx, dockerInput.Image, pullOpt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", errdefs.ErrImagePullFailed, dockerInput.Image, err)
+		}
+		defer pull.Close()
+
+		// download of docker image finishes at EOF of the pull request
+		if _, err := ioutil.ReadAll(pull); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", errdefs.ErrImagePullFailed, dockerInput.Image, err)
+		}
 	}
 
-	pullOpt := types.ImagePullOptions{}
-	if input.RegistryCred != "" {
-		pullOpt.RegistryAuth = input.RegistryCred
+	hostConfig := &container.HostConfig{
+		PortBindings: portBindings,
+		Mounts:       toDockerMounts(input.Mounts),
+		Privileged:   input.Privileged,
+		CapAdd:       input.CapAdd,
+		CapDrop:      input.CapDrop,
+		AutoRemove:   input.AutoRemove,
+		Resources: container.Resources{
+			Memory:   input.Resources.Memory,
+			NanoCPUs: input.Resources.NanoCPUs,
+		},
 	}
-	pull, err := cli.ImagePull(ctx, dockerInput.Image, pullOpt)
+
+	networkConfig := toNetworkingConfig(input.Networks, input.NetworkAliases)
+
+	resp, err := p.client.ContainerCreate(ctx, dockerInput, hostConfig, networkConfig, "")
 	if err != nil {
 		return nil, err
 	}
-	defer pull.Close()
 
-	// download of docker image finishes at EOF of the pull request
-	_, err = ioutil.ReadAll(pull)
-	if err != nil {
-		return nil, err
+	if len(input.Networks) > 1 {
+		if err := p.connectNetworks(ctx, resp.ID, input.Networks, input.NetworkAliases); err != nil {
+			return nil, err
+		}
 	}
 
-	hostConfig := &container.HostConfig{
-		PortBindings: portBindings,
+	for _, f := range input.Files {
+		if err := copyFileToContainer(ctx, p.client, resp.ID, f); err != nil {
+			return nil, fmt.Errorf("copy %s to container: %w", f.ContainerPath, err)
+		}
 	}
 
-	resp, err := cli.ContainerCreate(ctx, dockerInput, hostConfig, nil, "")
+	return &DockerContainer{
+		ID:       resp.ID,
+		provider: p,
+	}, nil
+}
+
+// RunContainer creates and starts a container, waiting on its WaitStrategy
+// if one was supplied.
+func (p *DockerProvider) RunContainer(ctx context.Context, containerImage string, input RequestContainer) (Container, error) {
+	c, err := p.CreateContainer(ctx, containerImage, input)
 	if err != nil {
 		return nil, err
 	}
-	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
-		return nil, err
-	}
-	containerInstance := &Container{
-		ID: resp.ID,
+
+	if err := c.Start(ctx); err != nil {
+		return c, err
 	}
 
 	// if a WaitStrategy has been specified, wait before returning
 	if input.WaitingFor != nil {
-		if err := input.WaitingFor.WaitUntilReady(ctx, containerInstance); err != nil {
-			// return containerInstance for termination
-			return containerInstance, err
+		if err := input.WaitingFor.WaitUntilReady(ctx, c); err != nil {
+			// return the container for termination
+			return c, fmt.Errorf("%w: %v", errdefs.ErrWaitTimeout, err)
+		}
+	}
+	return c, nil
+}
+
+// reuseHashLabel tags every container with a stable hash of the request
+// that created it, so a later ReuseOrCreate call -- from this process or
+// any other -- can find a matching container regardless of session.
+const reuseHashLabel = "org.testcontainers.reuse-hash"
+
+// reuseIdentity is the subset of a request that defines whether two
+// RequestContainers describe "the same" container for reuse purposes.
+// WaitingFor, SkipReaper and similar caller-local concerns are excluded
+// on purpose.
+type reuseIdentity struct {
+	Image        string
+	Env          map[string]string
+	ExportedPort []string
+	Cmd          []string
+	Entrypoint   []string
+	Networks     []string
+	User         string
+	WorkingDir   string
+}
+
+func reuseHash(containerImage string, input RequestContainer) string {
+	raw, _ := json.Marshal(reuseIdentity{
+		Image:        containerImage,
+		Env:          input.Env,
+		ExportedPort: input.ExportedPort,
+		Cmd:          input.Cmd,
+		Entrypoint:   input.Entrypoint,
+		Networks:     input.Networks,
+		User:         input.User,
+		WorkingDir:   input.WorkingDir,
+	})
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// ReuseOrCreate returns a running container created -- by this process
+// or an earlier one -- from a request with the same reuseIdentity, or
+// creates and starts a new one. Fields outside reuseIdentity (Mounts,
+// Files, Labels, FromDockerfile, Privileged, ...) are not compared.
+func (p *DockerProvider) ReuseOrCreate(ctx context.Context, containerImage string, input RequestContainer) (Container, error) {
+	filterArgs := filters.NewArgs()
+	filterArgs.Add("label", fmt.Sprintf("%s=%s", reuseHashLabel, reuseHash(containerImage, input)))
+	filterArgs.Add("status", "running")
+
+	existing, err := p.client.ContainerList(ctx, types.ContainerListOptions{
+		Filters: filterArgs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		c := &DockerContainer{ID: existing[0].ID, provider: p}
+		if input.WaitingFor != nil {
+			if err := input.WaitingFor.WaitUntilReady(ctx, c); err != nil {
+				return c, fmt.Errorf("%w: %v", errdefs.ErrWaitTimeout, err)
+			}
 		}
+		return c, nil
 	}
-	return containerInstance, nil
+
+	return p.RunContainer(ctx, containerImage, input)
 }
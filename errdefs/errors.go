@@ -0,0 +1,85 @@
+// Package errdefs defines typed error sentinels for this module, the
+// same approach moby itself adopted when it removed string matching
+// from API error handling.
+package errdefs
+
+import (
+	"errors"
+
+	dockererrdefs "github.com/docker/docker/errdefs"
+)
+
+var (
+	// ErrContainerNotFound is returned when a container ID no longer
+	// exists on the daemon.
+	ErrContainerNotFound = errors.New("container not found")
+	// ErrImagePullFailed is returned when pulling an image fails.
+	ErrImagePullFailed = errors.New("image pull failed")
+	// ErrPortNotMapped is returned when a requested container port has no
+	// corresponding host binding.
+	ErrPortNotMapped = errors.New("port not mapped")
+	// ErrWaitTimeout is returned when a WaitStrategy's startup timeout
+	// elapses before the target becomes ready.
+	ErrWaitTimeout = errors.New("wait: startup timeout exceeded")
+)
+
+type notFoundError struct{ cause error }
+
+func (e *notFoundError) Error() string { return e.cause.Error() }
+func (e *notFoundError) Unwrap() error { return e.cause }
+func (e *notFoundError) NotFound()     {}
+
+// Is reports a match against ErrContainerNotFound so callers can use
+// errors.Is(err, errdefs.ErrContainerNotFound) instead of IsNotFound when
+// they specifically care about a missing container, while Unwrap still
+// exposes the underlying docker client error.
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrContainerNotFound
+}
+
+// WrapNotFound marks err so that IsNotFound(err) reports true.
+func WrapNotFound(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &notFoundError{cause: err}
+}
+
+type conflictError struct{ cause error }
+
+func (e *conflictError) Error() string { return e.cause.Error() }
+func (e *conflictError) Unwrap() error { return e.cause }
+func (e *conflictError) Conflict()     {}
+
+// WrapConflict marks err so that IsConflict(err) reports true.
+func WrapConflict(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &conflictError{cause: err}
+}
+
+type hasNotFound interface{ NotFound() }
+type hasConflict interface{ Conflict() }
+
+// IsNotFound reports whether err, or any error it wraps, represents a
+// resource that doesn't exist -- either because this package wrapped it
+// with WrapNotFound, or because it's a docker client error the docker
+// SDK itself classifies as not-found.
+func IsNotFound(err error) bool {
+	var nf hasNotFound
+	if errors.As(err, &nf) {
+		return true
+	}
+	return dockererrdefs.IsNotFound(err)
+}
+
+// IsConflict reports whether err, or any error it wraps, represents a
+// request that conflicts with the current state of the target resource.
+func IsConflict(err error) bool {
+	var c hasConflict
+	if errors.As(err, &c) {
+		return true
+	}
+	return dockererrdefs.IsConflict(err)
+}
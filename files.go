@@ -0,0 +1,69 @@
+package testcontainer
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// ContainerFile describes a single file to copy into a container before
+// it starts. Either HostPath or Content must be set; Content takes
+// precedence when both are present.
+type ContainerFile struct {
+	// HostPath is read from disk when Content is nil.
+	HostPath string
+	// Content is used verbatim when set, letting callers write files that
+	// only exist in memory.
+	Content []byte
+	// ContainerPath is the absolute path the file is written to inside
+	// the container.
+	ContainerPath string
+	// FileMode is applied to the file inside the tar archive.
+	FileMode int64
+}
+
+func (f ContainerFile) contents() ([]byte, error) {
+	if f.Content != nil {
+		return f.Content, nil
+	}
+	return ioutil.ReadFile(f.HostPath)
+}
+
+// copyFileToContainer tars a single ContainerFile and copies it into the
+// container at its configured path via CopyToContainer.
+func copyFileToContainer(ctx context.Context, cli *client.Client, containerID string, f ContainerFile) error {
+	content, err := f.contents()
+	if err != nil {
+		return fmt.Errorf("read %s: %w", f.HostPath, err)
+	}
+
+	mode := f.FileMode
+	if mode == 0 {
+		mode = 0644
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	hdr := &tar.Header{
+		Name: filepath.Base(f.ContainerPath),
+		Mode: mode,
+		Size: int64(len(content)),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := tw.Write(content); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return cli.CopyToContainer(ctx, containerID, filepath.Dir(f.ContainerPath), bytes.NewReader(buf.Bytes()), types.CopyToContainerOptions{})
+}
@@ -0,0 +1,55 @@
+package testcontainer
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+)
+
+// Container is the interface implemented by every container backend this
+// module supports. Depending on it instead of a concrete struct lets
+// callers inject a fake provider in tests.
+type Container interface {
+	GetContainerID() string
+	Endpoint(ctx context.Context, proto string) (string, error)
+	PortEndpoint(ctx context.Context, port int, proto string) (string, error)
+	Host(ctx context.Context) (string, error)
+	MappedPort(ctx context.Context, port int) (int, error)
+	Inspect(ctx context.Context) (*types.ContainerJSON, error)
+	Name(ctx context.Context) (string, error)
+	Ports(ctx context.Context) (nat.PortMap, error)
+	State(ctx context.Context) (*types.ContainerState, error)
+	Logs(ctx context.Context) (io.ReadCloser, error)
+	FollowLogs(ctx context.Context, consumer LogConsumer) error
+	Exec(ctx context.Context, cmd []string) (int, io.Reader, error)
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+	Terminate(ctx context.Context, t *testing.T) error
+}
+
+// ContainerProvider knows how to create and run containers for a
+// particular backend (the Docker daemon today; podman or a remote
+// DOCKER_HOST are natural future implementations).
+type ContainerProvider interface {
+	// CreateContainer creates a container without starting it.
+	CreateContainer(ctx context.Context, image string, req RequestContainer) (Container, error)
+	// RunContainer creates and starts a container, waiting on its
+	// WaitStrategy if one was supplied.
+	RunContainer(ctx context.Context, image string, req RequestContainer) (Container, error)
+	// ReuseOrCreate returns a running container created from an equivalent
+	// request if one already exists, or creates and starts a new one.
+	ReuseOrCreate(ctx context.Context, image string, req RequestContainer) (Container, error)
+}
+
+// RunContainer is a convenience wrapper around DockerProvider.RunContainer
+// for callers who don't need to swap in an alternative backend.
+func RunContainer(ctx context.Context, containerImage string, input RequestContainer) (Container, error) {
+	provider, err := NewDockerProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.RunContainer(ctx, containerImage, input)
+}
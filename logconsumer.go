@@ -0,0 +1,73 @@
+package testcontainer
+
+import (
+	"bufio"
+	"context"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// LogType identifies which stream a Log line was written to.
+type LogType string
+
+const (
+	StdoutLog LogType = "STDOUT"
+	StderrLog LogType = "STDERR"
+)
+
+// Log is a single line of container output delivered to a LogConsumer.
+type Log struct {
+	LogType LogType
+	Content []byte
+}
+
+// LogConsumer receives demultiplexed container output as it is streamed
+// by FollowLogs. Implementations are typically used by wait strategies
+// that grep container logs, or by tests capturing diagnostics after a
+// failure.
+type LogConsumer interface {
+	Accept(Log)
+}
+
+// FollowLogs streams the container's stdout and stderr to consumer,
+// demultiplexing them with stdcopy, until ctx is canceled or the
+// container stops logging.
+func (c *DockerContainer) FollowLogs(ctx context.Context, consumer LogConsumer) error {
+	rc, err := c.provider.client.ContainerLogs(ctx, c.ID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+	})
+	if err != nil {
+		return err
+	}
+
+	stdoutR, stdoutW := io.Pipe()
+	stderrR, stderrW := io.Pipe()
+
+	go func() {
+		defer stdoutW.Close()
+		defer stderrW.Close()
+		_, _ = stdcopy.StdCopy(stdoutW, stderrW, rc)
+	}()
+
+	go streamLogLines(stdoutR, StdoutLog, consumer)
+	go streamLogLines(stderrR, StderrLog, consumer)
+
+	go func() {
+		<-ctx.Done()
+		rc.Close()
+	}()
+
+	return nil
+}
+
+func streamLogLines(r io.Reader, logType LogType, consumer LogConsumer) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := append([]byte(nil), scanner.Bytes()...)
+		consumer.Accept(Log{LogType: logType, Content: line})
+	}
+}
@@ -0,0 +1,56 @@
+package testcontainer
+
+import (
+	"github.com/docker/docker/api/types/mount"
+)
+
+// MountType identifies the kind of mount a ContainerMount describes.
+type MountType int
+
+const (
+	// MountTypeBind bind-mounts a path from the host.
+	MountTypeBind MountType = iota
+	// MountTypeVolume mounts a named Docker volume.
+	MountTypeVolume
+	// MountTypeTmpfs mounts an in-memory tmpfs.
+	MountTypeTmpfs
+)
+
+// ContainerMount describes a single mount to attach to a container.
+// Source is a host path for MountTypeBind, a volume name for
+// MountTypeVolume, and ignored for MountTypeTmpfs.
+type ContainerMount struct {
+	Type     MountType
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+func (m ContainerMount) toDockerMount() mount.Mount {
+	dm := mount.Mount{
+		Target:   m.Target,
+		ReadOnly: m.ReadOnly,
+	}
+	switch m.Type {
+	case MountTypeVolume:
+		dm.Type = mount.TypeVolume
+		dm.Source = m.Source
+	case MountTypeTmpfs:
+		dm.Type = mount.TypeTmpfs
+	default:
+		dm.Type = mount.TypeBind
+		dm.Source = m.Source
+	}
+	return dm
+}
+
+func toDockerMounts(mounts []ContainerMount) []mount.Mount {
+	if len(mounts) == 0 {
+		return nil
+	}
+	dockerMounts := make([]mount.Mount, 0, len(mounts))
+	for _, m := range mounts {
+		dockerMounts = append(dockerMounts, m.toDockerMount())
+	}
+	return dockerMounts
+}
@@ -0,0 +1,37 @@
+package testcontainer
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types/network"
+)
+
+// toNetworkingConfig builds the NetworkingConfig for ContainerCreate.
+// Docker only accepts one network at creation time; any additional
+// networks are attached afterwards with connectNetworks.
+func toNetworkingConfig(networks []string, aliases map[string][]string) *network.NetworkingConfig {
+	if len(networks) == 0 {
+		return nil
+	}
+	return &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			networks[0]: {
+				Aliases: aliases[networks[0]],
+			},
+		},
+	}
+}
+
+// connectNetworks attaches the container to every network beyond the
+// first, which ContainerCreate already attached it to.
+func (p *DockerProvider) connectNetworks(ctx context.Context, containerID string, networks []string, aliases map[string][]string) error {
+	for _, n := range networks[1:] {
+		err := p.client.NetworkConnect(ctx, n, containerID, &network.EndpointSettings{
+			Aliases: aliases[n],
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
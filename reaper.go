@@ -0,0 +1,183 @@
+package testcontainer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/google/uuid"
+)
+
+const (
+	// reaperImage is the side container that watches for orphaned
+	// resources, mirroring upstream testcontainers' Ryuk.
+	reaperImage = "testcontainers/ryuk:0.3.0"
+
+	// sessionLabel is set on every container, network and volume this
+	// module creates, except containers requested with SkipReaper, so the
+	// reaper knows what to remove if the test process dies before
+	// Terminate runs.
+	sessionLabel = "org.testcontainers.session-id"
+
+	reaperPort = "8080/tcp"
+
+	// reaperHeartbeatInterval is how often we poke the connection to keep
+	// it from going idle. It is independent of RYUK_RECONNECTION_TIMEOUT:
+	// that knob is Ryuk's own grace period after the connection actually
+	// drops, whereas what holds the session open is the TCP connection
+	// itself, not the cadence of these writes.
+	reaperHeartbeatInterval = 10 * time.Second
+)
+
+var (
+	reaperInstance *reaper
+	reaperOnce     sync.Once
+	reaperErr      error
+
+	sessionID     string
+	sessionIDOnce sync.Once
+)
+
+// sessionUUID returns the UUID shared by every resource this process
+// starts, so the reaper can clean them up as a group.
+func sessionUUID() string {
+	sessionIDOnce.Do(func() {
+		sessionID = uuid.New().String()
+	})
+	return sessionID
+}
+
+// sessionLabels returns the labels every created container, network and
+// volume should carry.
+func sessionLabels() map[string]string {
+	return map[string]string{sessionLabel: sessionUUID()}
+}
+
+// reaperDisabled reports whether TESTCONTAINERS_RYUK_DISABLED opts the
+// process out of the reaper entirely.
+func reaperDisabled() bool {
+	return os.Getenv("TESTCONTAINERS_RYUK_DISABLED") == "true"
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+// reaper holds the connection to the running Ryuk container. Every
+// label filter registered over the connection is removed by Ryuk once
+// the connection drops and stays down for RYUK_RECONNECTION_TIMEOUT.
+type reaper struct {
+	conn net.Conn
+}
+
+// getReaper starts the reaper container on first use and returns the
+// singleton connection for the process, or nil if the reaper has been
+// disabled.
+func getReaper(ctx context.Context, cli *client.Client) (*reaper, error) {
+	if reaperDisabled() {
+		return nil, nil
+	}
+	reaperOnce.Do(func() {
+		reaperInstance, reaperErr = newReaper(ctx, cli)
+	})
+	return reaperInstance, reaperErr
+}
+
+func newReaper(ctx context.Context, cli *client.Client) (*reaper, error) {
+	pull, err := cli.ImagePull(ctx, reaperImage, types.ImagePullOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("reaper: pull image: %w", err)
+	}
+	defer pull.Close()
+
+	// download of docker image finishes at EOF of the pull request
+	if _, err := ioutil.ReadAll(pull); err != nil {
+		return nil, fmt.Errorf("reaper: pull image: %w", err)
+	}
+
+	resp, err := cli.ContainerCreate(ctx, &container.Config{
+		Image:        reaperImage,
+		ExposedPorts: nat.PortSet{reaperPort: {}},
+		Env: []string{
+			"RYUK_CONNECTION_TIMEOUT=" + envDuration("RYUK_CONNECTION_TIMEOUT", 60*time.Second).String(),
+			"RYUK_RECONNECTION_TIMEOUT=" + envDuration("RYUK_RECONNECTION_TIMEOUT", 10*time.Second).String(),
+		},
+	}, &container.HostConfig{
+		Binds:      []string{"/var/run/docker.sock:/var/run/docker.sock"},
+		AutoRemove: true,
+		PortBindings: nat.PortMap{
+			reaperPort: []nat.PortBinding{{}},
+		},
+	}, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("reaper: create container: %w", err)
+	}
+
+	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return nil, fmt.Errorf("reaper: start container: %w", err)
+	}
+
+	inspect, err := cli.ContainerInspect(ctx, resp.ID)
+	if err != nil {
+		return nil, fmt.Errorf("reaper: inspect container: %w", err)
+	}
+
+	bindings := inspect.NetworkSettings.Ports[nat.Port(reaperPort)]
+	if len(bindings) == 0 {
+		return nil, fmt.Errorf("reaper: container published no port for %s", reaperPort)
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, envDuration("RYUK_CONNECTION_TIMEOUT", 60*time.Second))
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(dialCtx, "tcp", net.JoinHostPort("localhost", bindings[0].HostPort))
+	if err != nil {
+		return nil, fmt.Errorf("reaper: connect: %w", err)
+	}
+
+	r := &reaper{conn: conn}
+	if err := r.registerFilter(sessionLabel, sessionUUID()); err != nil {
+		return nil, err
+	}
+	go r.heartbeat(reaperHeartbeatInterval)
+	return r, nil
+}
+
+// registerFilter streams a label filter to the reaper, telling it to
+// remove anything matching key=value once this connection drops.
+func (r *reaper) registerFilter(key, value string) error {
+	_, err := fmt.Fprintf(r.conn, "label=%s=%s\n", key, value)
+	return err
+}
+
+// heartbeat writes to the reaper connection every interval so the socket
+// never looks idle. What actually keeps Ryuk from pruning this session's
+// resources is this connection staying open; if it closes (process
+// crash, network partition), Ryuk prunes everything registered on it
+// once RYUK_RECONNECTION_TIMEOUT elapses with no new connection.
+func (r *reaper) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := fmt.Fprint(r.conn, "\n"); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ExecStrategy waits until running Cmd inside the target exits with
+// ExitCode.
+type ExecStrategy struct {
+	Cmd      []string
+	ExitCode int
+
+	startupTimeout time.Duration
+}
+
+// ForExec waits until cmd exits 0 inside the target container.
+func ForExec(cmd []string) *ExecStrategy {
+	return &ExecStrategy{
+		Cmd:            cmd,
+		ExitCode:       0,
+		startupTimeout: defaultStartupTimeout,
+	}
+}
+
+// WithExitCode requires a different exit code than 0.
+func (s *ExecStrategy) WithExitCode(code int) *ExecStrategy {
+	s.ExitCode = code
+	return s
+}
+
+// WithStartupTimeout overrides the default startup timeout.
+func (s *ExecStrategy) WithStartupTimeout(timeout time.Duration) *ExecStrategy {
+	s.startupTimeout = timeout
+	return s
+}
+
+func (s *ExecStrategy) WaitUntilReady(ctx context.Context, target StrategyTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, s.startupTimeout)
+	defer cancel()
+
+	for {
+		code, _, err := target.Exec(ctx, s.Cmd)
+		if err == nil && code == s.ExitCode {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait: timed out waiting for %v to exit %d: %w", s.Cmd, s.ExitCode, ctx.Err())
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// HealthStrategy waits until target's Docker healthcheck reports
+// "healthy".
+type HealthStrategy struct {
+	startupTimeout time.Duration
+}
+
+// ForHealthCheck waits on the container's own HEALTHCHECK status.
+func ForHealthCheck() *HealthStrategy {
+	return &HealthStrategy{startupTimeout: defaultStartupTimeout}
+}
+
+// WithStartupTimeout overrides the default startup timeout.
+func (s *HealthStrategy) WithStartupTimeout(timeout time.Duration) *HealthStrategy {
+	s.startupTimeout = timeout
+	return s
+}
+
+func (s *HealthStrategy) WaitUntilReady(ctx context.Context, target StrategyTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, s.startupTimeout)
+	defer cancel()
+
+	for {
+		state, err := target.State(ctx)
+		if err == nil && state.Health != nil && state.Health.Status == "healthy" {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait: timed out waiting for healthy status: %w", ctx.Err())
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+)
+
+// HostPortStrategy waits until target's mapped Port accepts TCP
+// connections.
+type HostPortStrategy struct {
+	Port           nat.Port
+	startupTimeout time.Duration
+}
+
+// ForListeningPort waits until port is mapped and accepting connections.
+func ForListeningPort(port nat.Port) *HostPortStrategy {
+	return &HostPortStrategy{
+		Port:           port,
+		startupTimeout: defaultStartupTimeout,
+	}
+}
+
+// WithStartupTimeout overrides the default startup timeout.
+func (s *HostPortStrategy) WithStartupTimeout(timeout time.Duration) *HostPortStrategy {
+	s.startupTimeout = timeout
+	return s
+}
+
+func (s *HostPortStrategy) WaitUntilReady(ctx context.Context, target StrategyTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, s.startupTimeout)
+	defer cancel()
+
+	host, err := target.Host(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if mapped, err := target.MappedPort(ctx, s.Port.Int()); err == nil {
+			conn, dialErr := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", host, mapped), time.Second)
+			if dialErr == nil {
+				conn.Close()
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait: timed out waiting for port %s to listen: %w", s.Port, ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package wait
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+)
+
+// HTTPStrategy waits until an HTTP GET against target's mapped Port
+// matches StatusCodeMatcher.
+type HTTPStrategy struct {
+	Path              string
+	Port              nat.Port
+	StatusCodeMatcher func(status int) bool
+
+	tlsConfig      *tls.Config
+	basicAuthUser  string
+	basicAuthPass  string
+	startupTimeout time.Duration
+}
+
+// ForHTTP waits until a GET to path returns a 2xx status code.
+func ForHTTP(path string) *HTTPStrategy {
+	return &HTTPStrategy{
+		Path: path,
+		StatusCodeMatcher: func(status int) bool {
+			return status >= 200 && status < 300
+		},
+		startupTimeout: defaultStartupTimeout,
+	}
+}
+
+// WithPort sets the container port the request is sent to.
+func (s *HTTPStrategy) WithPort(port nat.Port) *HTTPStrategy {
+	s.Port = port
+	return s
+}
+
+// WithStatusCodeMatcher replaces the default 2xx status check.
+func (s *HTTPStrategy) WithStatusCodeMatcher(matcher func(status int) bool) *HTTPStrategy {
+	s.StatusCodeMatcher = matcher
+	return s
+}
+
+// WithTLS sends the request over https using config.
+func (s *HTTPStrategy) WithTLS(config *tls.Config) *HTTPStrategy {
+	s.tlsConfig = config
+	return s
+}
+
+// WithBasicAuth sets credentials sent with every request.
+func (s *HTTPStrategy) WithBasicAuth(username, password string) *HTTPStrategy {
+	s.basicAuthUser = username
+	s.basicAuthPass = password
+	return s
+}
+
+// WithStartupTimeout overrides the default startup timeout.
+func (s *HTTPStrategy) WithStartupTimeout(timeout time.Duration) *HTTPStrategy {
+	s.startupTimeout = timeout
+	return s
+}
+
+func (s *HTTPStrategy) WaitUntilReady(ctx context.Context, target StrategyTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, s.startupTimeout)
+	defer cancel()
+
+	host, err := target.Host(ctx)
+	if err != nil {
+		return err
+	}
+	mapped, err := target.MappedPort(ctx, s.Port.Int())
+	if err != nil {
+		return err
+	}
+
+	scheme := "http"
+	transport := &http.Transport{}
+	if s.tlsConfig != nil {
+		scheme = "https"
+		transport.TLSClientConfig = s.tlsConfig
+	}
+	client := &http.Client{Transport: transport}
+	url := fmt.Sprintf("%s://%s:%d%s", scheme, host, mapped, s.Path)
+
+	for {
+		if ready, err := s.probe(ctx, client, url); err == nil && ready {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait: timed out waiting for %s: %w", url, ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func (s *HTTPStrategy) probe(ctx context.Context, client *http.Client, url string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	if s.basicAuthUser != "" {
+		req.SetBasicAuth(s.basicAuthUser, s.basicAuthPass)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	return s.StatusCodeMatcher(resp.StatusCode), nil
+}
@@ -0,0 +1,73 @@
+package wait
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LogStrategy waits until Log has appeared in the target's output at
+// least Occurrence times.
+type LogStrategy struct {
+	Log        string
+	Occurrence int
+
+	startupTimeout time.Duration
+}
+
+// ForLog waits until log appears at least once in the target's output.
+func ForLog(log string) *LogStrategy {
+	return &LogStrategy{
+		Log:            log,
+		Occurrence:     1,
+		startupTimeout: defaultStartupTimeout,
+	}
+}
+
+// WithOccurrence requires log to appear at least n times.
+func (s *LogStrategy) WithOccurrence(n int) *LogStrategy {
+	s.Occurrence = n
+	return s
+}
+
+// WithStartupTimeout overrides the default startup timeout.
+func (s *LogStrategy) WithStartupTimeout(timeout time.Duration) *LogStrategy {
+	s.startupTimeout = timeout
+	return s
+}
+
+func (s *LogStrategy) WaitUntilReady(ctx context.Context, target StrategyTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, s.startupTimeout)
+	defer cancel()
+
+	for {
+		seen, err := s.count(ctx, target)
+		if err == nil && seen >= s.Occurrence {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait: timed out waiting for log %q: %w", s.Log, ctx.Err())
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
+
+func (s *LogStrategy) count(ctx context.Context, target StrategyTarget) (int, error) {
+	rc, err := target.Logs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	occurrences := 0
+	scanner := bufio.NewScanner(rc)
+	for scanner.Scan() {
+		if strings.Contains(scanner.Text(), s.Log) {
+			occurrences++
+		}
+	}
+	return occurrences, nil
+}
@@ -0,0 +1,72 @@
+package wait
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+)
+
+// SQLURLFunc builds a driver-specific DSN from the target's host and
+// mapped port.
+type SQLURLFunc func(host string, port int) string
+
+// SQLStrategy waits until a database/sql connection to target's mapped
+// Port can be opened and pinged.
+type SQLStrategy struct {
+	Port   nat.Port
+	Driver string
+	URL    SQLURLFunc
+
+	startupTimeout time.Duration
+}
+
+// ForSQL waits until a connection opened with driver against the DSN
+// built by urlFn can be pinged.
+func ForSQL(port nat.Port, driver string, urlFn SQLURLFunc) *SQLStrategy {
+	return &SQLStrategy{
+		Port:           port,
+		Driver:         driver,
+		URL:            urlFn,
+		startupTimeout: defaultStartupTimeout,
+	}
+}
+
+// WithStartupTimeout overrides the default startup timeout.
+func (s *SQLStrategy) WithStartupTimeout(timeout time.Duration) *SQLStrategy {
+	s.startupTimeout = timeout
+	return s
+}
+
+func (s *SQLStrategy) WaitUntilReady(ctx context.Context, target StrategyTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, s.startupTimeout)
+	defer cancel()
+
+	host, err := target.Host(ctx)
+	if err != nil {
+		return err
+	}
+	mapped, err := target.MappedPort(ctx, s.Port.Int())
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open(s.Driver, s.URL(host, mapped))
+	if err != nil {
+		return fmt.Errorf("wait: open %s: %w", s.Driver, err)
+	}
+	defer db.Close()
+
+	for {
+		if err := db.PingContext(ctx); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait: timed out waiting for %s to accept connections: %w", s.Driver, ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Package wait provides a batteries-included set of strategies for
+// waiting until a container is ready to accept traffic before a test
+// proceeds.
+package wait
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+// defaultStartupTimeout is used by every strategy unless overridden with
+// WithStartupTimeout.
+const defaultStartupTimeout = 60 * time.Second
+
+// StrategyTarget is the subset of the Container interface a WaitStrategy
+// needs to poll readiness. Strategies depend on this instead of a
+// concrete container type so they work against any backend.
+type StrategyTarget interface {
+	Host(ctx context.Context) (string, error)
+	MappedPort(ctx context.Context, port int) (int, error)
+	Inspect(ctx context.Context) (*types.ContainerJSON, error)
+	State(ctx context.Context) (*types.ContainerState, error)
+	Logs(ctx context.Context) (io.ReadCloser, error)
+	Exec(ctx context.Context, cmd []string) (int, io.Reader, error)
+}
+
+// WaitStrategy waits until target is considered ready, or returns an
+// error if it never becomes ready before its own timeout elapses.
+type WaitStrategy interface {
+	WaitUntilReady(ctx context.Context, target StrategyTarget) error
+}